@@ -0,0 +1,308 @@
+package kloud
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/koding/kite"
+	"github.com/koding/kite/protocol"
+)
+
+// errNoHealthyPeers is returned by a Selector when the discovery pool
+// it was given is empty.
+var errNoHealthyPeers = errors.New("kloud: no healthy kontrol peers available")
+
+// Selector picks one healthy peer out of a pool of *kite.Client.
+//
+// Implementations must be safe for concurrent use.
+type Selector interface {
+	Select(peers []*kite.Client) (*kite.Client, error)
+}
+
+// RoundRobinSelector cycles through peers in the order it is given
+// them; the discovery pool hands its peers over sorted by URL. It is
+// the default Selector used by KiteTransport.
+type RoundRobinSelector struct {
+	mu   sync.Mutex
+	next int
+}
+
+var _ Selector = (*RoundRobinSelector)(nil)
+
+// Select implements the Selector interface.
+func (s *RoundRobinSelector) Select(peers []*kite.Client) (*kite.Client, error) {
+	if len(peers) == 0 {
+		return nil, errNoHealthyPeers
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := peers[s.next%len(peers)]
+	s.next++
+
+	return p, nil
+}
+
+// LeastLatencySelector picks the peer with the lowest latency recorded
+// by the discovery pool's background health checks. Peers with no
+// recorded latency yet are treated as worst-case, so they get probed
+// before being favored over an already-measured peer.
+type LeastLatencySelector struct {
+	mu      sync.Mutex
+	latency map[string]time.Duration
+}
+
+var _ Selector = (*LeastLatencySelector)(nil)
+
+// Select implements the Selector interface.
+func (s *LeastLatencySelector) Select(peers []*kite.Client) (*kite.Client, error) {
+	if len(peers) == 0 {
+		return nil, errNoHealthyPeers
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	best, bestLatency := peers[0], s.latencyLocked(peers[0].URL)
+
+	for _, p := range peers[1:] {
+		if l := s.latencyLocked(p.URL); l < bestLatency {
+			best, bestLatency = p, l
+		}
+	}
+
+	return best, nil
+}
+
+func (s *LeastLatencySelector) latencyLocked(url string) time.Duration {
+	if l, ok := s.latency[url]; ok {
+		return l
+	}
+	return time.Duration(1<<63 - 1)
+}
+
+func (s *LeastLatencySelector) record(url string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.latency == nil {
+		s.latency = make(map[string]time.Duration)
+	}
+	s.latency[url] = d
+}
+
+// discoveryPool resolves and caches the set of kite.Clients returned by
+// Kontrol for a KiteTransport, health-checking them in the background
+// and re-resolving once the cache expires or the pool runs dry.
+type discoveryPool struct {
+	kt *KiteTransport
+
+	mu      sync.Mutex
+	peers   map[string]*kite.Client
+	expires time.Time
+
+	healthOnce sync.Once
+}
+
+func newDiscoveryPool(kt *KiteTransport) *discoveryPool {
+	return &discoveryPool{kt: kt}
+}
+
+// Get returns a peer selected by kt.Selector, re-resolving the pool
+// from Kontrol first if it is empty or has expired.
+func (p *discoveryPool) Get() (*kite.Client, error) {
+	peers, err := p.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	p.healthOnce.Do(func() { go p.healthCheck() })
+
+	return p.kt.selector().Select(peers)
+}
+
+// evict removes c from the pool, e.g. after a failed Call, forcing the
+// next Get to pick a different peer.
+func (p *discoveryPool) evict(c *kite.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.peers, c.URL)
+}
+
+func (p *discoveryPool) resolve() ([]*kite.Client, error) {
+	if p.kt.offline() {
+		return p.loadDisk()
+	}
+
+	p.mu.Lock()
+	fresh := len(p.peers) != 0 && time.Now().Before(p.expires)
+	p.mu.Unlock()
+
+	if fresh {
+		return p.snapshot(), nil
+	}
+
+	clients, err := p.kt.kite().GetKites(p.query())
+	if err == nil {
+		clients = p.dial(clients)
+		if len(clients) == 0 {
+			err = errNoHealthyPeers
+		}
+	}
+
+	if err != nil {
+		// Kontrol is unreachable, or returned nothing dialable - fall
+		// back to whatever peers we already know about in memory, then
+		// to the on-disk KontrolCache, marking the transport as
+		// degraded either way.
+		if peers := p.snapshot(); len(peers) != 0 {
+			p.kt.setDegraded(true)
+			return peers, nil
+		}
+
+		if peers, cacheErr := p.loadDisk(); cacheErr == nil && len(peers) != 0 {
+			p.kt.setDegraded(true)
+			return peers, nil
+		}
+
+		return nil, err
+	}
+
+	p.kt.setDegraded(false)
+
+	p.mu.Lock()
+	p.peers = make(map[string]*kite.Client, len(clients))
+	for _, c := range clients {
+		p.peers[c.URL] = c
+	}
+	p.expires = time.Now().Add(p.kt.discoveryRefresh())
+	p.mu.Unlock()
+
+	go p.saveDisk(clients)
+
+	return p.snapshot(), nil
+}
+
+// dial connects every client in clients, dropping the ones that fail.
+// GetKites and the KontrolCache only hand back addresses, not
+// connected clients, so every peer needs to be dialed before it can be
+// used for Call or the "kite.ping" health check.
+func (p *discoveryPool) dial(clients []*kite.Client) []*kite.Client {
+	dialed := make([]*kite.Client, 0, len(clients))
+	for _, c := range clients {
+		if err := c.DialTimeout(p.kt.dialTimeout()); err != nil {
+			p.kt.log().Warning("kloud: dropping undialable peer %s: %s", c.URL, err)
+			continue
+		}
+		dialed = append(dialed, c)
+	}
+	return dialed
+}
+
+// loadDisk populates the pool from the on-disk KontrolCache, used both
+// in --offline mode and as a last resort when Kontrol is unreachable.
+func (p *discoveryPool) loadDisk() ([]*kite.Client, error) {
+	entry, err := p.kt.kontrolCache().Get(p.query(), p.kt.kiteConfig().KiteKey, p.kt.kontrolCacheMaxAge())
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make([]*kite.Client, 0, len(entry.URLs))
+	for _, url := range entry.URLs {
+		clients = append(clients, p.kt.kite().NewClient(url))
+	}
+
+	clients = p.dial(clients)
+	if len(clients) == 0 {
+		return nil, errNoHealthyPeers
+	}
+
+	p.mu.Lock()
+	p.peers = make(map[string]*kite.Client, len(clients))
+	for _, c := range clients {
+		p.peers[c.URL] = c
+	}
+	p.mu.Unlock()
+
+	return p.snapshot(), nil
+}
+
+// saveDisk persists a successful Kontrol resolution to the
+// KontrolCache, so it can be used as a fallback the next time Kontrol
+// is unreachable.
+func (p *discoveryPool) saveDisk(clients []*kite.Client) {
+	urls := make([]string, len(clients))
+	for i, c := range clients {
+		urls[i] = c.URL
+	}
+
+	kiteKey := p.kt.kiteConfig().KiteKey
+
+	if err := p.kt.kontrolCache().Set(p.query(), urls, kiteKey); err != nil {
+		p.kt.log().Error("kloud: failed to persist kontrol cache: %s", err)
+	}
+}
+
+// refresh forces the next resolve to re-query Kontrol, used by
+// healthCheck to asynchronously recover once a degraded transport's
+// peer is reachable again.
+func (p *discoveryPool) refresh() {
+	if p.kt.offline() {
+		return
+	}
+
+	p.mu.Lock()
+	p.expires = time.Time{}
+	p.mu.Unlock()
+
+	if _, err := p.resolve(); err != nil {
+		p.kt.log().Warning("kloud: kontrol still unreachable: %s", err)
+	}
+}
+
+func (p *discoveryPool) query() *protocol.KontrolQuery {
+	return &protocol.KontrolQuery{
+		Name:        p.kt.kiteName(),
+		Environment: p.kt.kiteConfig().Environment,
+		Region:      p.kt.region(),
+	}
+}
+
+func (p *discoveryPool) snapshot() []*kite.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	peers := make([]*kite.Client, 0, len(p.peers))
+	for _, c := range p.peers {
+		peers = append(peers, c)
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].URL < peers[j].URL })
+	return peers
+}
+
+func (p *discoveryPool) healthCheck() {
+	interval := p.kt.healthInterval()
+
+	for range time.Tick(interval) {
+		if p.kt.Status() == StatusDegraded {
+			p.refresh()
+		}
+
+		for _, c := range p.snapshot() {
+			start := time.Now()
+
+			if _, err := c.TellWithTimeout("kite.ping", interval, nil); err != nil {
+				p.evict(c)
+				continue
+			}
+
+			if sel, ok := p.kt.selector().(*LeastLatencySelector); ok {
+				sel.record(c.URL, time.Since(start))
+			}
+		}
+	}
+}
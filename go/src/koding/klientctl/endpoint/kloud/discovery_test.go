@@ -0,0 +1,60 @@
+package kloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/koding/kite"
+)
+
+func TestRoundRobinSelector(t *testing.T) {
+	peers := []*kite.Client{{URL: "a"}, {URL: "b"}, {URL: "c"}}
+
+	var s RoundRobinSelector
+
+	for i, want := range []string{"a", "b", "c", "a"} {
+		p, err := s.Select(peers)
+		if err != nil {
+			t.Fatalf("Select(%d): %s", i, err)
+		}
+		if p.URL != want {
+			t.Errorf("Select(%d) = %q, want %q", i, p.URL, want)
+		}
+	}
+
+	if _, err := s.Select(nil); err == nil {
+		t.Error("Select(nil): expected error, got nil")
+	}
+}
+
+func TestDiscoveryRefresh(t *testing.T) {
+	kt := &KiteTransport{DiscoveryRefresh: 42 * time.Second}
+
+	if got := kt.discoveryRefresh(); got != kt.DiscoveryRefresh {
+		t.Errorf("discoveryRefresh() = %s, want %s", got, kt.DiscoveryRefresh)
+	}
+
+	if got, want := (&KiteTransport{}).discoveryRefresh(), 5*time.Minute; got != want {
+		t.Errorf("discoveryRefresh() = %s, want %s", got, want)
+	}
+}
+
+func TestLeastLatencySelector(t *testing.T) {
+	peers := []*kite.Client{{URL: "a"}, {URL: "b"}}
+
+	var s LeastLatencySelector
+	s.record("a", 100*time.Millisecond)
+	s.record("b", 10*time.Millisecond)
+
+	p, err := s.Select(peers)
+	if err != nil {
+		t.Fatalf("Select: %s", err)
+	}
+	if p.URL != "b" {
+		t.Errorf("Select() = %q, want %q", p.URL, "b")
+	}
+
+	if _, err := s.Select(nil); err == nil {
+		t.Error("Select(nil): expected error, got nil")
+	}
+}
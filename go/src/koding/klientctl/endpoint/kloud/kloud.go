@@ -1,6 +1,9 @@
 package kloud
 
 import (
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	cfg "koding/kites/config"
@@ -11,7 +14,6 @@ import (
 
 	"github.com/koding/kite"
 	kitecfg "github.com/koding/kite/config"
-	"github.com/koding/kite/protocol"
 	"github.com/koding/logging"
 )
 
@@ -21,8 +23,8 @@ var kdCacheOpts = configstore.CacheOptions("kd")
 // RPC round trip.
 //
 // Default implementation used in this package is
-// a kiteTransport, but plain net/rpc can also be
-// used.
+// KiteTransport, but NetRPCTransport and GRPCTransport
+// can also be used - see NewTransport and DefaultTransport.
 type Transport interface {
 	Call(method string, arg, reply interface{}) error
 }
@@ -31,9 +33,23 @@ type Transport interface {
 var DefaultLog logging.Logger = logging.NewCustom("endpoint-kloud", false)
 
 // DefaultClient is a default client used by Cache, Kite,
-// KiteConfig and Kloud functions.
+// KiteConfig and Kloud functions. Its Transport is picked by
+// DefaultTransport, so it honors config.Konfig's Kloud endpoint
+// protocol instead of hard-wiring KiteTransport.
 var DefaultClient = &Client{
-	Transport: &KiteTransport{},
+	Transport: defaultTransport(),
+}
+
+// defaultTransport calls DefaultTransport, falling back to a zero
+// KiteTransport on error so a misconfigured protocol doesn't make
+// DefaultClient itself unusable.
+func defaultTransport() Transport {
+	t, err := DefaultTransport()
+	if err != nil {
+		DefaultLog.Error("kloud: %s; falling back to KiteTransport", err)
+		return &KiteTransport{}
+	}
+	return t
 }
 
 // Client is responsible for communication with Kloud kite.
@@ -46,6 +62,9 @@ type Client struct {
 	cache *cfg.Cache
 }
 
+// Cache returns the on-disk bolt-backed cache shared by Cache, Kite,
+// KiteConfig and Kloud functions. It also backs KontrolCache, used by
+// KiteTransport to survive a Kontrol outage.
 func (c *Client) Cache() *cfg.Cache {
 	if c.cache != nil {
 		return c.cache
@@ -107,21 +126,87 @@ type KiteTransport struct {
 	// If nil, DefaultLog is going to be used instead.
 	Log logging.Logger
 
+	// DiscoveryRefresh is how long a Kontrol-resolved pool of kites is
+	// cached for before GetKites is queried again. github.com/koding/kite
+	// does not advertise a per-result TTL, so this fixed window is the
+	// only thing that bounds the cache's staleness.
+	//
+	// If zero, 5m is going to be used instead.
+	//
+	// Only used when KiteURL is empty.
+	DiscoveryRefresh time.Duration
+
+	// HealthInterval is how often peers in the discovery pool are
+	// pinged with "kite.ping" to evict unhealthy ones.
+	//
+	// If zero, 30s is going to be used instead.
+	//
+	// Only used when KiteURL is empty.
+	HealthInterval time.Duration
+
+	// Selector picks a peer out of the discovery pool for each Call.
+	//
+	// If nil, a *RoundRobinSelector is going to be used instead.
+	//
+	// Only used when KiteURL is empty.
+	Selector Selector
+
+	// Region restricts Kontrol discovery to kites in this region, and
+	// is part of the KontrolCache key.
+	Region string
+
+	// Offline, when true, makes the transport skip Kontrol entirely
+	// and rely only on its KontrolCache and KiteURL.
+	//
+	// If false, the KD_OFFLINE=1 environment variable is checked as
+	// well.
+	Offline bool
+
+	// KontrolCacheMaxAge bounds how old a KontrolCache entry can be
+	// before it is treated as a miss instead of being served as a
+	// fallback.
+	//
+	// If zero, 24h is going to be used instead.
+	KontrolCacheMaxAge time.Duration
+
 	k      *kite.Kite
 	kCfg   *kitecfg.Config
 	kKloud *kite.Client
+
+	poolOnce sync.Once
+	pool     *discoveryPool
+	sel      Selector
+
+	kcacheOnce sync.Once
+	kcache     *KontrolCache
+
+	degraded int32
 }
 
 var _ Transport = (*KiteTransport)(nil)
 var _ stack.Validator = (*KiteTransport)(nil)
 
 func (kt *KiteTransport) Call(method string, arg, reply interface{}) error {
-	k, err := kt.kloud()
+	k, err := kt.peer()
 	if err != nil {
 		return err
 	}
 
 	r, err := k.TellWithTimeout(method, kt.tellTimeout(), arg)
+	if err != nil && kt.KiteURL == "" && isTransportError(err) {
+		// Current peer looks dead - evict it from the discovery pool
+		// and retry once against a different one. A non-transport
+		// error is the remote method's own application error, which
+		// retrying against another peer would just double-execute.
+		kt.discoveryPool().evict(k)
+
+		k, err = kt.peer()
+		if err != nil {
+			return err
+		}
+
+		r, err = k.TellWithTimeout(method, kt.tellTimeout(), arg)
+	}
 	if err != nil {
 		return err
 	}
@@ -133,6 +218,19 @@ func (kt *KiteTransport) Call(method string, arg, reply interface{}) error {
 	return nil
 }
 
+// isTransportError reports whether err indicates a dead connection to
+// the peer, as opposed to an application error returned by the remote
+// method itself. Only a transport error justifies evicting the peer
+// and retrying on another one - kite.Error is how a remote method's
+// own failure (e.g. a validation error on stack.apply) comes back
+// through the same TellWithTimeout return, and retrying a
+// non-idempotent method against a second kloud instance because of it
+// would double-execute it.
+func isTransportError(err error) bool {
+	_, isRemoteErr := err.(*kite.Error)
+	return !isRemoteErr
+}
+
 func (kt *KiteTransport) kite() *kite.Kite {
 	if kt.k != nil {
 		return kt.k
@@ -158,7 +256,29 @@ func (kt *KiteTransport) kiteConfig() *kitecfg.Config {
 	return kt.kCfg
 }
 
-func (kt *KiteTransport) kloud() (*kite.Client, error) {
+// peer returns the *kite.Client to use for the next Call.
+//
+// When KiteURL is set, it is dialed once and memoized for the lifetime
+// of the transport. Otherwise a peer is selected from the discovery
+// pool on every call - so load is actually spread across the pool by
+// kt.Selector, instead of pinning every call to whichever peer answered
+// first.
+func (kt *KiteTransport) peer() (*kite.Client, error) {
+	if kt.KiteURL != "" {
+		return kt.direct()
+	}
+
+	peer, err := kt.discoveryPool().Get()
+	if err != nil {
+		return nil, err
+	}
+
+	kt.auth(peer)
+
+	return peer, nil
+}
+
+func (kt *KiteTransport) direct() (*kite.Client, error) {
 	if kt.kKloud != nil {
 		return kt.kKloud, nil
 	}
@@ -166,33 +286,56 @@ func (kt *KiteTransport) kloud() (*kite.Client, error) {
 	kloud := kt.kite().NewClient(kt.kiteURL())
 
 	if err := kloud.DialTimeout(kt.dialTimeout()); err != nil {
-		query := &protocol.KontrolQuery{
-			Name:        kt.kiteName(),
-			Environment: kt.kiteConfig().Environment,
-		}
-
-		clients, err := kt.kite().GetKites(query)
-		if err != nil {
-			return nil, err
-		}
-
-		kloud = kt.kite().NewClient(clients[0].URL)
-
-		if err := kloud.DialTimeout(kt.DialTimeout); err != nil {
-			return nil, err
-		}
+		return nil, err
 	}
 
 	kt.kKloud = kloud
+	kt.auth(kt.kKloud)
 
+	return kt.kKloud, nil
+}
+
+func (kt *KiteTransport) auth(c *kite.Client) {
 	if kitekey := kt.kiteConfig().KiteKey; kitekey != "" {
-		kt.kKloud.Auth = &kite.Auth{
+		c.Auth = &kite.Auth{
 			Type: "kiteKey",
 			Key:  kitekey,
 		}
 	}
+}
+
+// discoveryPool lazily builds kt.pool. It is guarded by a sync.Once,
+// rather than the bare nil-check used by kt.k/kt.kCfg/kt.kKloud, since
+// concurrent Call and Stream invocations can reach it at once.
+func (kt *KiteTransport) discoveryPool() *discoveryPool {
+	kt.poolOnce.Do(func() {
+		kt.pool = newDiscoveryPool(kt)
+	})
+	return kt.pool
+}
+
+func (kt *KiteTransport) selector() Selector {
+	if kt.Selector != nil {
+		return kt.Selector
+	}
+	if kt.sel == nil {
+		kt.sel = &RoundRobinSelector{}
+	}
+	return kt.sel
+}
 
-	return kt.kKloud, nil
+func (kt *KiteTransport) discoveryRefresh() time.Duration {
+	if kt.DiscoveryRefresh != 0 {
+		return kt.DiscoveryRefresh
+	}
+	return 5 * time.Minute
+}
+
+func (kt *KiteTransport) healthInterval() time.Duration {
+	if kt.HealthInterval != 0 {
+		return kt.HealthInterval
+	}
+	return 30 * time.Second
 }
 
 func (kt *KiteTransport) dialTimeout() time.Duration {
@@ -237,6 +380,52 @@ func (kt *KiteTransport) kiteName() string {
 	return "kloud"
 }
 
+func (kt *KiteTransport) region() string {
+	return kt.Region
+}
+
+func (kt *KiteTransport) offline() bool {
+	if kt.Offline {
+		return true
+	}
+	return os.Getenv("KD_OFFLINE") == "1"
+}
+
+// kontrolCache lazily builds kt.kcache. Like discoveryPool, it is
+// guarded by a sync.Once since saveDisk and loadDisk can reach it from
+// different goroutines at once.
+func (kt *KiteTransport) kontrolCache() *KontrolCache {
+	kt.kcacheOnce.Do(func() {
+		kt.kcache = NewKontrolCache(Cache())
+	})
+	return kt.kcache
+}
+
+func (kt *KiteTransport) kontrolCacheMaxAge() time.Duration {
+	if kt.KontrolCacheMaxAge != 0 {
+		return kt.KontrolCacheMaxAge
+	}
+	return 24 * time.Hour
+}
+
+func (kt *KiteTransport) setDegraded(degraded bool) {
+	var v int32
+	if degraded {
+		v = 1
+	}
+	atomic.StoreInt32(&kt.degraded, v)
+}
+
+// Status implements the StatusReporter interface.
+func (kt *KiteTransport) Status() Status {
+	if atomic.LoadInt32(&kt.degraded) == 1 {
+		return StatusDegraded
+	}
+	return StatusOK
+}
+
+var _ StatusReporter = (*KiteTransport)(nil)
+
 func (kt *KiteTransport) Valid() error {
 	// In order to test whether we're able to authenticate with kloud
 	// we need to call some kite method. For that purpose we
@@ -0,0 +1,18 @@
+package kloud
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/koding/kite"
+)
+
+func TestIsTransportError(t *testing.T) {
+	if isTransportError(&kite.Error{Type: "validationError", Message: "boom"}) {
+		t.Error("isTransportError(*kite.Error) = true, want false")
+	}
+
+	if !isTransportError(errors.New("connection refused")) {
+		t.Error("isTransportError(plain error) = false, want true")
+	}
+}
@@ -0,0 +1,5 @@
+// Package kloudrpc contains the generated gRPC client/server stubs used
+// by kloud.GRPCTransport.
+package kloudrpc
+
+//go:generate protoc -I . --go_out=plugins=grpc:. kloud.proto
@@ -0,0 +1,93 @@
+package kloud
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	cfg "koding/kites/config"
+
+	"github.com/koding/kite/protocol"
+)
+
+// errCacheKeyMismatch is returned by Get when a cache entry exists for
+// the query but was stored under a different kite key.
+var errCacheKeyMismatch = errors.New("kloud: kontrol cache entry belongs to a different kite key")
+
+// errCacheExpired is returned by Get when a cache entry exists for the
+// query but is older than the requested maxAge.
+var errCacheExpired = errors.New("kloud: kontrol cache entry expired")
+
+// kontrolCacheKeyPrefix namespaces KontrolCache entries inside the
+// shared kd Cache, so they don't collide with other values stored in
+// it under plain string keys.
+const kontrolCacheKeyPrefix = "kontrolcache."
+
+// KontrolCacheEntry is the persisted result of a single successful
+// Kontrol GetKites query.
+type KontrolCacheEntry struct {
+	// URLs are the kite URLs returned by Kontrol for the query.
+	URLs []string `json:"urls"`
+
+	// Timestamp is when the query was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Fingerprint is a hash of the kite key used for the query, so a
+	// cache entry is never reused across different credentials.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// KontrolCache persists the last successful Kontrol GetKites result per
+// (Name, Environment, Region) query tuple in a *cfg.Cache, so
+// KiteTransport can keep working when Kontrol is unreachable.
+type KontrolCache struct {
+	cache *cfg.Cache
+}
+
+// NewKontrolCache returns a KontrolCache backed by cache.
+func NewKontrolCache(cache *cfg.Cache) *KontrolCache {
+	return &KontrolCache{cache: cache}
+}
+
+// Get returns the cached entry for query, provided it was stored under
+// the same kiteKey and is no older than maxAge (a maxAge of zero means
+// no age limit). A fingerprint mismatch or an expired entry is
+// reported as an error, just like a cache miss, so callers don't need
+// to special-case it.
+func (kc *KontrolCache) Get(query *protocol.KontrolQuery, kiteKey string, maxAge time.Duration) (*KontrolCacheEntry, error) {
+	var e KontrolCacheEntry
+	if err := kc.cache.Get(kc.key(query), &e); err != nil {
+		return nil, err
+	}
+
+	if e.Fingerprint != fingerprint(kiteKey) {
+		return nil, errCacheKeyMismatch
+	}
+
+	if maxAge > 0 && time.Since(e.Timestamp) > maxAge {
+		return nil, errCacheExpired
+	}
+
+	return &e, nil
+}
+
+// Set persists the result of query - the URLs of the kites it resolved
+// to - fingerprinted with kiteKey.
+func (kc *KontrolCache) Set(query *protocol.KontrolQuery, urls []string, kiteKey string) error {
+	e := &KontrolCacheEntry{
+		URLs:        urls,
+		Timestamp:   time.Now(),
+		Fingerprint: fingerprint(kiteKey),
+	}
+	return kc.cache.Set(kc.key(query), e)
+}
+
+func (kc *KontrolCache) key(query *protocol.KontrolQuery) string {
+	return kontrolCacheKeyPrefix + query.Name + "." + query.Environment + "." + query.Region
+}
+
+func fingerprint(kiteKey string) string {
+	sum := sha1.Sum([]byte(kiteKey))
+	return hex.EncodeToString(sum[:])
+}
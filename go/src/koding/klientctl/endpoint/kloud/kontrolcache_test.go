@@ -0,0 +1,103 @@
+package kloud
+
+import (
+	"testing"
+	"time"
+
+	cfg "koding/kites/config"
+	"koding/kites/config/configstore"
+
+	"github.com/koding/kite/protocol"
+)
+
+// newTestCache returns a real, disk-backed *cfg.Cache namespaced under
+// a name unique to the calling test, so KontrolCache round-trip tests
+// exercise the same storage Client.Cache() does in production instead
+// of a hand-rolled stub.
+func newTestCache(t *testing.T) *cfg.Cache {
+	c := cfg.NewCache(configstore.CacheOptions("kloud-kontrolcache-test-" + t.Name()))
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestKontrolCacheGetSet(t *testing.T) {
+	kc := NewKontrolCache(newTestCache(t))
+	query := &protocol.KontrolQuery{Name: "kloud", Environment: "production", Region: "default"}
+
+	if err := kc.Set(query, []string{"ws://10.0.0.1:56789/kite"}, "kite-key-a"); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+
+	entry, err := kc.Get(query, "kite-key-a", 0)
+	if err != nil {
+		t.Fatalf("Get: %s", err)
+	}
+	if len(entry.URLs) != 1 || entry.URLs[0] != "ws://10.0.0.1:56789/kite" {
+		t.Errorf("Get().URLs = %v, want [%q]", entry.URLs, "ws://10.0.0.1:56789/kite")
+	}
+}
+
+func TestKontrolCacheGetKeyMismatch(t *testing.T) {
+	kc := NewKontrolCache(newTestCache(t))
+	query := &protocol.KontrolQuery{Name: "kloud", Environment: "production", Region: "default"}
+
+	if err := kc.Set(query, []string{"ws://10.0.0.1:56789/kite"}, "kite-key-a"); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+
+	if _, err := kc.Get(query, "kite-key-b", 0); err != errCacheKeyMismatch {
+		t.Errorf("Get() with a different kite key = %v, want %v", err, errCacheKeyMismatch)
+	}
+}
+
+func TestKontrolCacheGetExpired(t *testing.T) {
+	kc := NewKontrolCache(newTestCache(t))
+	query := &protocol.KontrolQuery{Name: "kloud", Environment: "production", Region: "default"}
+
+	if err := kc.Set(query, []string{"ws://10.0.0.1:56789/kite"}, "kite-key-a"); err != nil {
+		t.Fatalf("Set: %s", err)
+	}
+
+	time.Sleep(time.Millisecond)
+
+	if _, err := kc.Get(query, "kite-key-a", time.Millisecond); err != errCacheExpired {
+		t.Errorf("Get() past maxAge = %v, want %v", err, errCacheExpired)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := fingerprint("key-a")
+	b := fingerprint("key-b")
+
+	if a == "" {
+		t.Fatal("fingerprint(\"key-a\") is empty")
+	}
+	if a == b {
+		t.Errorf("fingerprint(%q) == fingerprint(%q), want distinct values", "key-a", "key-b")
+	}
+	if a != fingerprint("key-a") {
+		t.Error("fingerprint is not deterministic")
+	}
+}
+
+func TestClientStatusDefault(t *testing.T) {
+	c := &Client{Transport: unsupportedTransport{}}
+
+	if got := c.Status(); got != StatusOK {
+		t.Errorf("Status() = %v, want %v", got, StatusOK)
+	}
+}
+
+func TestKiteTransportStatus(t *testing.T) {
+	kt := &KiteTransport{}
+
+	if got := kt.Status(); got != StatusOK {
+		t.Errorf("Status() = %v, want %v", got, StatusOK)
+	}
+
+	kt.setDegraded(true)
+
+	if got := kt.Status(); got != StatusDegraded {
+		t.Errorf("Status() = %v, want %v", got, StatusDegraded)
+	}
+}
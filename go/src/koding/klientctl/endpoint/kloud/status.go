@@ -0,0 +1,40 @@
+package kloud
+
+// Status describes the health of a Client's underlying Transport.
+type Status int
+
+const (
+	// StatusOK means the Transport is operating normally.
+	StatusOK Status = iota
+
+	// StatusDegraded means the Transport is serving calls from stale
+	// or cached state, e.g. because Kontrol is unreachable and
+	// KiteTransport fell back to its KontrolCache.
+	StatusDegraded
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusDegraded:
+		return "degraded"
+	default:
+		return "ok"
+	}
+}
+
+// StatusReporter is implemented by Transports that can report being in
+// a degraded state.
+type StatusReporter interface {
+	Status() Status
+}
+
+// Status reports the health of c's Transport. Transports that do not
+// implement StatusReporter are always considered StatusOK.
+func (c *Client) Status() Status {
+	if sr, ok := c.Transport.(StatusReporter); ok {
+		return sr.Status()
+	}
+	return StatusOK
+}
+
+func Status() Status { return DefaultClient.Status() }
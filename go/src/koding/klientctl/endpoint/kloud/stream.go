@@ -0,0 +1,56 @@
+package kloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/koding/kite/dnode"
+)
+
+// Stream is a handle to a long-running, server-push RPC call, used by
+// methods like stack build/apply, machine exec or log tail that need to
+// report incremental progress instead of a single request/reply.
+type Stream interface {
+	// Recv blocks until the next message arrives, or returns the error
+	// that ended the stream (io.EOF on a clean, peer-initiated close).
+	Recv() (json.RawMessage, error)
+
+	// Close tears the stream down. It is safe to call multiple times.
+	Close() error
+}
+
+// Streamer is implemented by Transports that support Stream in addition
+// to the request/reply Call. ctx bounds the stream's lifetime: a
+// canceled ctx must tear the stream down promptly, e.g. when a killed
+// kd process stops reading from it.
+type Streamer interface {
+	Stream(ctx context.Context, method string, arg interface{}) (Stream, error)
+}
+
+// streamArg is the envelope every streaming method argument is wrapped
+// in before being sent over the wire: the peer is expected to invoke
+// the callback once per message, and signal completion either by
+// closing the connection or by invoking it with a nil frame.
+//
+// Only used by KiteTransport, whose dnode wire format can carry a
+// callback function alongside the regular argument.
+type streamArg struct {
+	Arg    interface{}    `json:"arg"`
+	Stream dnode.Function `json:"stream"`
+}
+
+// Stream calls method on c's Transport and returns a Stream of
+// its incremental replies. It returns an error if the Transport does
+// not implement Streamer. Canceling ctx tears the Stream down.
+func (c *Client) Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	s, ok := c.Transport.(Streamer)
+	if !ok {
+		return nil, fmt.Errorf("kloud: %T does not support streaming", c.Transport)
+	}
+	return s.Stream(ctx, method, arg)
+}
+
+func Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	return DefaultClient.Stream(ctx, method, arg)
+}
@@ -0,0 +1,123 @@
+package kloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/koding/kite/dnode"
+)
+
+var _ Streamer = (*KiteTransport)(nil)
+
+// Stream implements the Streamer interface. It registers a dnode
+// callback in the call argument (see streamArg) and forwards every
+// invocation the peer makes through it into a buffered channel, until
+// a nil frame, a context cancellation or a peer disconnect closes it.
+func (kt *KiteTransport) Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	k, err := kt.peer()
+	if err != nil {
+		return nil, err
+	}
+
+	s := newKiteStream()
+
+	wrapped := &streamArg{
+		Arg:    arg,
+		Stream: dnode.Function(s.onMessage),
+	}
+
+	if _, err := k.TellWithTimeout(method, kt.tellTimeout(), wrapped); err != nil {
+		return nil, err
+	}
+
+	k.OnDisconnect(func() {
+		s.fail(errors.New("kloud: peer disconnected"))
+	})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.fail(ctx.Err())
+		case <-s.closed:
+		}
+	}()
+
+	return s, nil
+}
+
+// kiteStream implements Stream on top of a dnode callback.
+type kiteStream struct {
+	msgs   chan json.RawMessage
+	closed chan struct{}
+	once   sync.Once
+
+	mu  sync.Mutex
+	err error
+}
+
+var _ Stream = (*kiteStream)(nil)
+
+func newKiteStream() *kiteStream {
+	return &kiteStream{
+		msgs:   make(chan json.RawMessage, 16),
+		closed: make(chan struct{}),
+	}
+}
+
+// onMessage is the dnode callback invoked by the peer for every
+// streamed frame. A nil args marks a clean, terminal frame.
+func (s *kiteStream) onMessage(args *dnode.Partial) {
+	if args == nil {
+		s.fail(io.EOF)
+		return
+	}
+
+	select {
+	case s.msgs <- json.RawMessage(args.Raw):
+	case <-s.closed:
+	}
+}
+
+func (s *kiteStream) fail(err error) {
+	s.mu.Lock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.mu.Unlock()
+
+	s.Close()
+}
+
+// Recv drains s.msgs with priority over s.closed, so frames buffered
+// before a terminal frame, a peer disconnect or a ctx cancellation are
+// always delivered before the error that ended the stream - otherwise
+// a racing select between the two channels could drop them.
+func (s *kiteStream) Recv() (json.RawMessage, error) {
+	select {
+	case m := <-s.msgs:
+		return m, nil
+	default:
+	}
+
+	select {
+	case m := <-s.msgs:
+		return m, nil
+	case <-s.closed:
+		s.mu.Lock()
+		err := s.err
+		s.mu.Unlock()
+
+		if err == nil {
+			err = io.EOF
+		}
+		return nil, err
+	}
+}
+
+func (s *kiteStream) Close() error {
+	s.once.Do(func() { close(s.closed) })
+	return nil
+}
@@ -0,0 +1,112 @@
+package kloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/rpc"
+)
+
+var _ Streamer = (*NetRPCTransport)(nil)
+
+// netRPCStreamOpenRequest/netRPCStreamOpenReply open a stream, the
+// net/rpc counterpart of KiteTransport's initial TellWithTimeout that
+// registers a dnode callback.
+type netRPCStreamOpenRequest struct {
+	Method string
+	Arg    json.RawMessage
+}
+
+type netRPCStreamOpenReply struct {
+	ID    string
+	Error string
+}
+
+// netRPCStreamNextRequest/netRPCStreamNextReply fetch the next frame of
+// an already-open stream. net/rpc has no native server-streaming, so
+// each frame is its own blocking request/reply call over the same
+// client Call already uses - the server is expected to hold the call
+// open until a frame is ready, Done is set, or an error occurs.
+type netRPCStreamNextRequest struct {
+	ID string
+}
+
+type netRPCStreamNextReply struct {
+	Reply json.RawMessage
+	Done  bool
+	Error string
+}
+
+// Stream implements the Streamer interface using the same *rpc.Client
+// (and therefore the same Addr/Codec) as Call, instead of a bespoke
+// connection and framing of its own - so a server built to answer Call
+// can answer Stream too. "Kloud.Stream" opens the stream server-side;
+// each subsequent "Kloud.StreamNext" blocks for the next frame, the
+// net/rpc equivalent of KiteTransport's dnode callback.
+func (t *NetRPCTransport) Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	c, err := t.conn()
+	if err != nil {
+		return nil, err
+	}
+
+	argRaw, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	var open netRPCStreamOpenReply
+	if err := c.Call("Kloud.Stream", &netRPCStreamOpenRequest{Method: method, Arg: argRaw}, &open); err != nil {
+		return nil, err
+	}
+	if open.Error != "" {
+		return nil, errors.New(open.Error)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &netRPCStream{client: c, id: open.ID, ctx: ctx, cancel: cancel}, nil
+}
+
+// netRPCStream implements Stream on top of repeated "Kloud.StreamNext"
+// calls against the stream opened by Stream.
+type netRPCStream struct {
+	client *rpc.Client
+	id     string
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+var _ Stream = (*netRPCStream)(nil)
+
+func (s *netRPCStream) Recv() (json.RawMessage, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var reply netRPCStreamNextReply
+	call := s.client.Go("Kloud.StreamNext", &netRPCStreamNextRequest{ID: s.id}, &reply, make(chan *rpc.Call, 1))
+
+	select {
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	case res := <-call.Done:
+		if res.Error != nil {
+			return nil, res.Error
+		}
+	}
+
+	if reply.Error != "" {
+		return nil, errors.New(reply.Error)
+	}
+	if reply.Done {
+		return nil, io.EOF
+	}
+
+	return reply.Reply, nil
+}
+
+func (s *netRPCStream) Close() error {
+	s.cancel()
+	return nil
+}
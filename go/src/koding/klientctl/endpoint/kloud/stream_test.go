@@ -0,0 +1,34 @@
+package kloud
+
+import (
+	"context"
+	"testing"
+)
+
+// TestStreamerParity ensures every Transport that can stream exposes it
+// under the same Streamer interface.
+func TestStreamerParity(t *testing.T) {
+	transports := []Transport{
+		&KiteTransport{},
+		&NetRPCTransport{},
+		&GRPCTransport{},
+	}
+
+	for _, tr := range transports {
+		if _, ok := tr.(Streamer); !ok {
+			t.Errorf("%T does not implement Streamer", tr)
+		}
+	}
+}
+
+func TestClientStreamUnsupported(t *testing.T) {
+	c := &Client{Transport: unsupportedTransport{}}
+
+	if _, err := c.Stream(context.Background(), "kite.print", nil); err == nil {
+		t.Error("Stream: expected error for a non-streaming Transport, got nil")
+	}
+}
+
+type unsupportedTransport struct{}
+
+func (unsupportedTransport) Call(method string, arg, reply interface{}) error { return nil }
@@ -0,0 +1,36 @@
+package kloud
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
+
+// Tail consumes a streaming method, such as a stack build/apply or a
+// machine exec's log output, invoking fn for every incremental message
+// until the stream ends or ctx is canceled. It is the streaming
+// counterpart of Call, built on top of Stream/Streamer.
+func (c *Client) Tail(ctx context.Context, method string, arg interface{}, fn func(msg json.RawMessage)) error {
+	s, err := c.Stream(ctx, method, arg)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	for {
+		msg, err := s.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		fn(msg)
+	}
+}
+
+// Tail calls method on the default Transport via Tail.
+func Tail(ctx context.Context, method string, arg interface{}, fn func(msg json.RawMessage)) error {
+	return DefaultClient.Tail(ctx, method, arg, fn)
+}
@@ -0,0 +1,43 @@
+package kloud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestClientTailUnsupported(t *testing.T) {
+	c := &Client{Transport: unsupportedTransport{}}
+
+	err := c.Tail(context.Background(), "kite.print", nil, func(json.RawMessage) {})
+	if err == nil {
+		t.Error("Tail: expected error for a non-streaming Transport, got nil")
+	}
+}
+
+func TestClientTailRecvError(t *testing.T) {
+	c := &Client{Transport: &fakeStreamTransport{err: errors.New("boom")}}
+
+	err := c.Tail(context.Background(), "kite.print", nil, func(json.RawMessage) {})
+	if err == nil {
+		t.Error("Tail: expected error from a failing stream, got nil")
+	}
+}
+
+type fakeStreamTransport struct {
+	err error
+}
+
+func (fakeStreamTransport) Call(method string, arg, reply interface{}) error { return nil }
+
+func (t *fakeStreamTransport) Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	return &fakeStream{err: t.err}, nil
+}
+
+type fakeStream struct {
+	err error
+}
+
+func (s *fakeStream) Recv() (json.RawMessage, error) { return nil, s.err }
+func (s *fakeStream) Close() error                   { return nil }
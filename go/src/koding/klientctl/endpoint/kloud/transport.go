@@ -0,0 +1,60 @@
+package kloud
+
+import (
+	"fmt"
+
+	"koding/klientctl/config"
+)
+
+// Protocol selects which Transport implementation NewTransport builds.
+type Protocol string
+
+const (
+	// ProtocolKite uses KiteTransport - the default, dnode-based
+	// transport provided by github.com/koding/kite.
+	ProtocolKite Protocol = "kite"
+
+	// ProtocolNetRPC uses NetRPCTransport.
+	ProtocolNetRPC Protocol = "netrpc"
+
+	// ProtocolGRPC uses GRPCTransport.
+	ProtocolGRPC Protocol = "grpc"
+)
+
+// NewTransport builds a Transport for the given Protocol, addressed at
+// addr. For ProtocolKite, addr is used as KiteTransport.KiteURL.
+func NewTransport(p Protocol, addr string) (Transport, error) {
+	switch p {
+	case "", ProtocolKite:
+		return &KiteTransport{KiteURL: addr}, nil
+	case ProtocolNetRPC:
+		return &NetRPCTransport{Addr: addr}, nil
+	case ProtocolGRPC:
+		return &GRPCTransport{Addr: addr}, nil
+	default:
+		return nil, fmt.Errorf("kloud: unknown transport protocol %q", p)
+	}
+}
+
+// DefaultTransport builds a Transport selected by config.Konfig's
+// Kloud endpoint protocol, falling back to KiteTransport when the
+// protocol is not set.
+//
+// NetRPCTransport and GRPCTransport dial a bare "host:port", unlike
+// KiteTransport.KiteURL which takes the full endpoint URL - so the
+// configured Public URL is normalized down to its host before it is
+// handed to either of them.
+func DefaultTransport() (Transport, error) {
+	kloud := config.Konfig.Endpoints.Kloud()
+	proto := Protocol(kloud.Protocol)
+
+	addr := kloud.Public.String()
+	if proto == ProtocolNetRPC || proto == ProtocolGRPC {
+		if kloud.Public.Host == "" {
+			return nil, fmt.Errorf("kloud: endpoint %q has no host:port for %q transport", kloud.Public, proto)
+		}
+		addr = kloud.Public.Host
+	}
+
+	return NewTransport(proto, addr)
+}
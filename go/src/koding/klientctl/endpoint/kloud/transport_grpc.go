@@ -0,0 +1,168 @@
+package kloud
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"koding/kites/kloud/stack"
+	"koding/klientctl/endpoint/kloud/kloudrpc"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// GRPCTransport is a Transport implementation that talks to kloud over
+// gRPC, using the kloudrpc.Kloud service generated from kloud.proto.
+//
+// Like NetRPCTransport, it exists as an alternative to KiteTransport for
+// networks where the dnode/WebSocket upgrade cannot be established.
+type GRPCTransport struct {
+	// Addr is the "host:port" of the kloud gRPC endpoint.
+	//
+	// Required.
+	Addr string
+
+	// DialOptions are extra grpc.DialOptions passed to grpc.Dial, e.g.
+	// grpc.WithTransportCredentials for TLS.
+	//
+	// If empty, grpc.WithInsecure() and grpc.WithBlock() are going to
+	// be used instead.
+	DialOptions []grpc.DialOption
+
+	// DialTimeout is a maximum time the endpoint is going to be
+	// dialed for.
+	//
+	// If zero, 30s is going to be used instead.
+	DialTimeout time.Duration
+
+	conn   *grpc.ClientConn
+	client kloudrpc.KloudClient
+}
+
+var _ Transport = (*GRPCTransport)(nil)
+var _ stack.Validator = (*GRPCTransport)(nil)
+
+// Call implements the Transport interface.
+func (t *GRPCTransport) Call(method string, arg, reply interface{}) error {
+	c, err := t.kloud()
+	if err != nil {
+		return err
+	}
+
+	argRaw, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.dialTimeout())
+	defer cancel()
+
+	resp, err := c.Call(ctx, &kloudrpc.Request{Method: method, Arg: argRaw})
+	if err != nil {
+		return err
+	}
+
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	if reply != nil && len(resp.Reply) != 0 {
+		return json.Unmarshal(resp.Reply, reply)
+	}
+
+	return nil
+}
+
+// Valid implements the stack.Validator interface.
+func (t *GRPCTransport) Valid() error {
+	return t.Call("kite.print", "", nil)
+}
+
+var _ Streamer = (*GRPCTransport)(nil)
+
+// Stream implements the Streamer interface using the service's native
+// server-streaming Stream RPC. Canceling ctx cancels the underlying
+// gRPC stream, so a killed kd process tears it down promptly.
+func (t *GRPCTransport) Stream(ctx context.Context, method string, arg interface{}) (Stream, error) {
+	c, err := t.kloud()
+	if err != nil {
+		return nil, err
+	}
+
+	argRaw, err := json.Marshal(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	stream, err := c.Stream(ctx, &kloudrpc.Request{Method: method, Arg: argRaw})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &grpcStream{stream: stream, cancel: cancel}, nil
+}
+
+// grpcStream implements Stream on top of a kloudrpc.Kloud_StreamClient.
+type grpcStream struct {
+	stream kloudrpc.Kloud_StreamClient
+	cancel context.CancelFunc
+}
+
+var _ Stream = (*grpcStream)(nil)
+
+func (s *grpcStream) Recv() (json.RawMessage, error) {
+	resp, err := s.stream.Recv()
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+
+	return json.RawMessage(resp.Reply), nil
+}
+
+func (s *grpcStream) Close() error {
+	s.cancel()
+	return nil
+}
+
+func (t *GRPCTransport) kloud() (kloudrpc.KloudClient, error) {
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	opts := t.dialOptions()
+
+	ctx, cancel := context.WithTimeout(context.Background(), t.dialTimeout())
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, t.Addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	t.conn = conn
+	t.client = kloudrpc.NewKloudClient(conn)
+
+	return t.client, nil
+}
+
+func (t *GRPCTransport) dialOptions() []grpc.DialOption {
+	if len(t.DialOptions) != 0 {
+		return t.DialOptions
+	}
+	return []grpc.DialOption{grpc.WithInsecure(), grpc.WithBlock()}
+}
+
+func (t *GRPCTransport) dialTimeout() time.Duration {
+	if t.DialTimeout != 0 {
+		return t.DialTimeout
+	}
+	return 30 * time.Second
+}
@@ -0,0 +1,152 @@
+package kloud
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"time"
+
+	"koding/kites/kloud/stack"
+)
+
+// netRPCRequest is the single envelope every kloud method call is
+// wrapped in before being dispatched through net/rpc. net/rpc's
+// Register/RegisterName only publish exported Go methods on a
+// registered receiver, so a kite method name like "stack.apply" can
+// never be used directly as the ServiceMethod string - it is instead
+// carried as data to the one exported "Kloud.Call" method, the
+// net/rpc counterpart of kloudrpc.Request{Method,Arg}.
+type netRPCRequest struct {
+	Method string
+	Arg    json.RawMessage
+}
+
+// netRPCReply is the envelope netRPCRequest is answered with.
+type netRPCReply struct {
+	Reply json.RawMessage
+	Error string
+}
+
+// NetRPCCodec selects the wire codec used by NetRPCTransport.
+type NetRPCCodec string
+
+const (
+	// NetRPCGob is the stdlib net/rpc gob codec. This is the default.
+	NetRPCGob NetRPCCodec = "gob"
+
+	// NetRPCJSON is the net/rpc/jsonrpc codec, useful when the kloud
+	// endpoint is fronted by something that prefers plain JSON over
+	// gob-encoded frames.
+	NetRPCJSON NetRPCCodec = "jsonrpc"
+)
+
+// NetRPCTransport is a Transport implementation that talks to kloud over
+// plain stdlib net/rpc, instead of github.com/koding/kite's dnode
+// protocol.
+//
+// It is meant for restricted networks where the WebSocket upgrade used
+// by KiteTransport is blocked, but a plain TCP (optionally TLS-wrapped)
+// connection to kloud is still reachable.
+type NetRPCTransport struct {
+	// Addr is the "host:port" of the kloud net/rpc endpoint.
+	//
+	// Required.
+	Addr string
+
+	// TLSConfig, when non-nil, makes the transport dial Addr over TLS.
+	TLSConfig *tls.Config
+
+	// Codec selects the wire codec.
+	//
+	// If empty, NetRPCGob is going to be used instead.
+	Codec NetRPCCodec
+
+	// DialTimeout is a maximum time the endpoint is going to be
+	// dialed for.
+	//
+	// If zero, 30s is going to be used instead.
+	DialTimeout time.Duration
+
+	client *rpc.Client
+}
+
+var _ Transport = (*NetRPCTransport)(nil)
+var _ stack.Validator = (*NetRPCTransport)(nil)
+
+// Call implements the Transport interface. It dispatches the kloud
+// method through the single exported "Kloud.Call" net/rpc method,
+// carrying the real method name and argument in netRPCRequest - see
+// its doc comment for why.
+func (t *NetRPCTransport) Call(method string, arg, reply interface{}) error {
+	c, err := t.conn()
+	if err != nil {
+		return err
+	}
+
+	argRaw, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+
+	var resp netRPCReply
+	if err := c.Call("Kloud.Call", &netRPCRequest{Method: method, Arg: argRaw}, &resp); err != nil {
+		return err
+	}
+
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+
+	if reply != nil && len(resp.Reply) != 0 {
+		return json.Unmarshal(resp.Reply, reply)
+	}
+
+	return nil
+}
+
+// Valid implements the stack.Validator interface.
+func (t *NetRPCTransport) Valid() error {
+	return t.Call("kite.print", "", nil)
+}
+
+func (t *NetRPCTransport) conn() (*rpc.Client, error) {
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	conn, err := net.DialTimeout("tcp", t.Addr, t.dialTimeout())
+	if err != nil {
+		return nil, fmt.Errorf("unable to dial %q: %s", t.Addr, err)
+	}
+
+	if t.TLSConfig != nil {
+		conn = tls.Client(conn, t.TLSConfig)
+	}
+
+	switch t.codec() {
+	case NetRPCJSON:
+		t.client = jsonrpc.NewClient(conn)
+	default:
+		t.client = rpc.NewClient(conn)
+	}
+
+	return t.client, nil
+}
+
+func (t *NetRPCTransport) codec() NetRPCCodec {
+	if t.Codec != "" {
+		return t.Codec
+	}
+	return NetRPCGob
+}
+
+func (t *NetRPCTransport) dialTimeout() time.Duration {
+	if t.DialTimeout != 0 {
+		return t.DialTimeout
+	}
+	return 30 * time.Second
+}
@@ -0,0 +1,82 @@
+package kloud
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTransportParity ensures every Transport implementation exposes the
+// same stack.Validator probe, regardless of the underlying wire
+// protocol.
+func TestTransportParity(t *testing.T) {
+	transports := map[string]interface {
+		Valid() error
+	}{
+		"kite":   &KiteTransport{KiteURL: "http://127.0.0.1:0/kite"},
+		"netrpc": &NetRPCTransport{Addr: "127.0.0.1:0"},
+		// grpc.WithBlock(), used by GRPCTransport, retries with backoff
+		// until the dial context deadline instead of failing fast on a
+		// refused connection - so a short DialTimeout is required here
+		// to keep this subtest as fast as its kite/netrpc siblings.
+		"grpc": &GRPCTransport{Addr: "127.0.0.1:0", DialTimeout: 100 * time.Millisecond},
+	}
+
+	for name, tr := range transports {
+		if err := tr.Valid(); err == nil {
+			t.Errorf("%s: expected Valid() to fail against an unreachable endpoint", name)
+		}
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	cases := []struct {
+		proto Protocol
+		want  interface{}
+	}{
+		{ProtocolKite, &KiteTransport{}},
+		{ProtocolNetRPC, &NetRPCTransport{}},
+		{ProtocolGRPC, &GRPCTransport{}},
+	}
+
+	for _, c := range cases {
+		tr, err := NewTransport(c.proto, "")
+		if err != nil {
+			t.Fatalf("NewTransport(%q): %s", c.proto, err)
+		}
+
+		switch c.want.(type) {
+		case *KiteTransport:
+			if _, ok := tr.(*KiteTransport); !ok {
+				t.Errorf("NewTransport(%q) = %T, want *KiteTransport", c.proto, tr)
+			}
+		case *NetRPCTransport:
+			if _, ok := tr.(*NetRPCTransport); !ok {
+				t.Errorf("NewTransport(%q) = %T, want *NetRPCTransport", c.proto, tr)
+			}
+		case *GRPCTransport:
+			if _, ok := tr.(*GRPCTransport); !ok {
+				t.Errorf("NewTransport(%q) = %T, want *GRPCTransport", c.proto, tr)
+			}
+		}
+	}
+
+	if _, err := NewTransport("bogus", ""); err == nil {
+		t.Error("NewTransport(\"bogus\", \"\"): expected error, got nil")
+	}
+}
+
+// TestDefaultTransport exercises DefaultTransport itself, rather than
+// NewTransport with a hand-fed addr, so a regression that leaves it
+// disconnected from config.Konfig's Kloud endpoint protocol is caught.
+func TestDefaultTransport(t *testing.T) {
+	tr, err := DefaultTransport()
+	if err != nil {
+		t.Fatalf("DefaultTransport(): %s", err)
+	}
+
+	// config.Konfig's Kloud endpoint protocol defaults to the empty
+	// string, which NewTransport treats as ProtocolKite.
+	if _, ok := tr.(*KiteTransport); !ok {
+		t.Errorf("DefaultTransport() = %T, want *KiteTransport", tr)
+	}
+}